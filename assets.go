@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// assets.go - asset pipeline for <link rel="stylesheet"> and <script src="...">
+// tags. Every ref (local file or remote https:// URL), discovered by
+// htmlrewrite.go's tree walk, is fetched, minified, and concatenated in
+// source order into a single inline block. With --keep-remote, remote
+// assets stay external instead, rewritten with a computed SHA-384
+// "integrity" attribute.
+
+// assetOptions controls how the pipeline treats remote tags.
+type assetOptions struct {
+	KeepRemote bool // leave remote tags external with an integrity attribute
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+var minifier = newMinifier()
+
+func newMinifier() *minify.M {
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+	return m
+}
+
+// isRemoteRef reports whether an href/src value is a remote URL.
+func isRemoteRef(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+}
+
+// fetchRef reads a local file relative to dir, or fetches a remote URL.
+func fetchRef(dir, ref string) ([]byte, error) {
+	if isRemoteRef(ref) {
+		resp, err := httpClient.Get(ref)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", ref, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: unexpected status %s", ref, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return os.ReadFile(filepath.Join(dir, ref))
+}
+
+// sriHash computes a subresource-integrity value in "sha384-<base64>" form.
+func sriHash(content []byte) string {
+	sum := sha512.Sum384(content)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// buildStylesheets fetches, minifies, and concatenates every stylesheet ref
+// into one <style> block. Remote refs are inlined with a provenance comment
+// unless opts.KeepRemote is set, in which case they're returned separately
+// as rewritten external <link> tags carrying an integrity attribute.
+func buildStylesheets(dir string, refs []string, opts assetOptions) (inline string, externalTags []string, err error) {
+	var combined strings.Builder
+	for _, ref := range refs {
+		content, err := fetchRef(dir, ref)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if isRemoteRef(ref) && opts.KeepRemote {
+			externalTags = append(externalTags, fmt.Sprintf(
+				`<link rel="stylesheet" href=%q integrity=%q crossorigin="anonymous">`,
+				ref, sriHash(content)))
+			continue
+		}
+
+		minified, err := minifier.Bytes("text/css", content)
+		if err != nil {
+			return "", nil, fmt.Errorf("minifying %s: %w", ref, err)
+		}
+		if isRemoteRef(ref) {
+			fmt.Fprintf(&combined, "/* from: %s */\n", ref)
+		}
+		combined.Write(minified)
+		combined.WriteString("\n")
+	}
+
+	if combined.Len() == 0 {
+		return "", externalTags, nil
+	}
+	return "<style>\n" + combined.String() + "</style>", externalTags, nil
+}
+
+// buildScripts fetches, minifies, and concatenates every external script ref
+// into one <script> block, the same way buildStylesheets does for CSS.
+func buildScripts(dir string, refs []string, opts assetOptions) (inline string, externalTags []string, err error) {
+	var combined strings.Builder
+	for _, ref := range refs {
+		content, err := fetchRef(dir, ref)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if isRemoteRef(ref) && opts.KeepRemote {
+			externalTags = append(externalTags, fmt.Sprintf(
+				`<script src=%q integrity=%q crossorigin="anonymous"></script>`,
+				ref, sriHash(content)))
+			continue
+		}
+
+		minified, err := minifier.Bytes("application/javascript", content)
+		if err != nil {
+			return "", nil, fmt.Errorf("minifying %s: %w", ref, err)
+		}
+		if isRemoteRef(ref) {
+			fmt.Fprintf(&combined, "/* from: %s */\n", ref)
+		}
+		combined.Write(minified)
+		combined.WriteString("\n")
+	}
+
+	if combined.Len() == 0 {
+		return "", externalTags, nil
+	}
+	return "<script>\n" + combined.String() + "</script>", externalTags, nil
+}
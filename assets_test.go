@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsRemoteRef(t *testing.T) {
+	tests := map[string]bool{
+		"https://cdn.example.com/a.css": true,
+		"http://cdn.example.com/a.css":  true,
+		"styles.css":                    false,
+		"./scripts.js":                  false,
+	}
+	for ref, want := range tests {
+		if got := isRemoteRef(ref); got != want {
+			t.Errorf("isRemoteRef(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestSRIHashIsStableAndPrefixed(t *testing.T) {
+	h1 := sriHash([]byte("body{color:red}"))
+	h2 := sriHash([]byte("body{color:red}"))
+	if h1 != h2 {
+		t.Fatalf("sriHash is not deterministic: %q != %q", h1, h2)
+	}
+	if !strings.HasPrefix(h1, "sha384-") {
+		t.Errorf("sriHash(...) = %q, want sha384- prefix", h1)
+	}
+
+	h3 := sriHash([]byte("body{color:blue}"))
+	if h1 == h3 {
+		t.Errorf("sriHash produced the same digest for different content")
+	}
+}
+
+func TestBuildStylesheetsInlinesAndMinifiesLocalRefs(t *testing.T) {
+	dir := t.TempDir()
+	css := "body {\n  color: red;\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "styles.css"), []byte(css), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inline, external, err := buildStylesheets(dir, []string{"styles.css"}, assetOptions{})
+	if err != nil {
+		t.Fatalf("buildStylesheets: %v", err)
+	}
+	if len(external) != 0 {
+		t.Errorf("external = %v, want none for a local ref", external)
+	}
+	if !strings.HasPrefix(inline, "<style>") || !strings.HasSuffix(inline, "</style>") {
+		t.Errorf("inline = %q, want a <style> block", inline)
+	}
+	if strings.Contains(inline, "\n  color") {
+		t.Errorf("inline = %q, want minified CSS", inline)
+	}
+}
@@ -1,119 +1,240 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"html/template"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
-// build.go - CLI build tool for Crissy Portfolio
-// Inlines styles.css, scripts.js, and crissy-data.json into a single HTML file
-// then copies that HTML to all configured output filenames.
+// build.go - one-shot build for the Crissy Portfolio
+// Runs index.html's <link>/<script> tags through the asset pipeline,
+// inlines crissy-data.json, then renders index.html as an html/template
+// once per route declared in crissy-data.json so each page gets its own
+// title and meta tags, auto-escaped against whatever route.Title etc.
+// happen to contain.
+
+// appScriptName is the <script src> build.go treats as the app's own logic:
+// it gets the per-route __CRISSY_DATA__ and the patched loadData, instead
+// of just being folded into the asset pipeline like any other script.
+const appScriptName = "scripts.js"
+
+// runBuild performs the one-shot build: render every route and write it to
+// build/ under its own filename.
+func runBuild(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	keepRemote := fs.Bool("keep-remote", false, "leave remote assets external with an integrity attribute instead of inlining them")
+	format := fs.String("format", formatSingle, "output format: single, static, or pdf")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
 
-func main() {
 	dir := "."
-	if len(os.Args) > 1 {
-		dir = os.Args[1]
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return fmt.Errorf("creating build directory: %w", err)
+	}
+
+	opts := assetOptions{KeepRemote: *keepRemote}
+
+	if err := discoverPlugins(dir); err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatStatic:
+		return buildStatic(dir, buildDir)
+	case formatPDF:
+		return buildPDF(dir, buildDir, opts)
+	case formatSingle:
+		pages, err := renderSite(dir, opts)
+		if err != nil {
+			return err
+		}
+		return writePages(buildDir, pages)
+	default:
+		return fmt.Errorf("unknown --format %q (want single, static, or pdf)", *format)
+	}
+}
+
+// writePages writes every rendered page to buildDir under its route filename.
+func writePages(buildDir string, pages []page) error {
+	for _, page := range pages {
+		outPath := filepath.Join(buildDir, page.Route.File)
+		if err := os.WriteFile(outPath, []byte(page.HTML), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", page.Route.File, err)
+		}
+		fmt.Printf("Built: %s\n", outPath)
 	}
+	fmt.Println("Build complete.")
+	return nil
+}
+
+// page is one rendered route: its metadata and the final HTML.
+type page struct {
+	Route Route
+	HTML  string
+}
 
+// renderSite reads index.html and crissy-data.json from dir, runs every
+// <link rel="stylesheet">/<script src> tag in index.html through the asset
+// pipeline, and returns the rendered HTML for every route, in declaration
+// order. Both runBuild and the serve dev server render through here so a
+// rebuild is always just "read + renderSite".
+func renderSite(dir string, opts assetOptions) ([]page, error) {
 	htmlPath := filepath.Join(dir, "index.html")
-	cssPath := filepath.Join(dir, "styles.css")
-	jsPath := filepath.Join(dir, "scripts.js")
 	jsonPath := filepath.Join(dir, "crissy-data.json")
 
 	htmlBytes, err := os.ReadFile(htmlPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading index.html: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("reading index.html: %w", err)
 	}
-	cssBytes, err := os.ReadFile(cssPath)
+	jsonBytes, err := os.ReadFile(jsonPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading styles.css: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("reading crissy-data.json: %w", err)
 	}
-	jsBytes, err := os.ReadFile(jsPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading scripts.js: %v\n", err)
-		os.Exit(1)
+
+	jsonData := string(jsonBytes)
+
+	var routesDocData routesDoc
+	if err := json.Unmarshal(jsonBytes, &routesDocData); err != nil {
+		return nil, fmt.Errorf("parsing crissy-data.json: %w", err)
 	}
-	jsonBytes, err := os.ReadFile(jsonPath)
+	routes := routesDocData.Routes
+	if len(routes) == 0 {
+		routes = defaultRoutes()
+	}
+
+	// Discover <link>/<script> refs from the raw, pre-template document.
+	// The asset pipeline output (below) gets folded in per route only after
+	// each route's own {{.Title}}-style substitution has already run on the
+	// untouched source, so arbitrary braces inside inlined CSS/JS can never
+	// reach html/template.
+	rawDoc, err := parseHTML(string(htmlBytes))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading crissy-data.json: %v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	html := string(htmlBytes)
-	css := string(cssBytes)
-	js := string(jsBytes)
-	jsonData := string(jsonBytes)
+	// Stylesheets are the same for every route, so the pipeline runs once.
+	cssInline, cssExternalTags, err := buildStylesheets(dir, findStylesheetRefs(rawDoc), opts)
+	if err != nil {
+		return nil, err
+	}
 
-	// Replace the external CSS link with inline style
-	inlineCSS := "<style>\n" + css + "\n</style>"
-	html = replaceLinkTag(html, inlineCSS)
+	// Scripts split into the app's own logic (patched per route below) and
+	// everything else (vendor/remote scripts), which the pipeline handles
+	// the same way it handles stylesheets.
+	var appJSSrc string
+	var otherScriptRefs []string
+	for _, ref := range findScriptRefs(rawDoc) {
+		if ref == appScriptName {
+			appJSSrc = ref
+		} else {
+			otherScriptRefs = append(otherScriptRefs, ref)
+		}
+	}
 
-	// Replace the external JS script with inline script that embeds JSON data
-	inlineJS := "<script>\nvar __CRISSY_DATA__ = " + jsonData + ";\n</script>\n"
-	inlineJS += "<script>\n" + patchJSForInline(js) + "\n</script>"
-	html = replaceScriptTag(html, inlineJS)
+	var appJS string
+	if appJSSrc != "" {
+		raw, err := fetchRef(dir, appJSSrc)
+		if err != nil {
+			return nil, err
+		}
+		appJS = patchJSForInline(string(raw))
+	}
 
-	// Output files: the 4 pages that should all be identical
-	outputFiles := []string{
-		"index.html",
-		"projects.html",
-		"links.html",
-		"about.html",
+	pipelineJS, jsExternalTags, err := buildScripts(dir, otherScriptRefs, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	buildDir := filepath.Join(dir, "build")
-	err = os.MkdirAll(buildDir, 0755)
+	cspCfg, err := loadCSPConfig(dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating build directory: %v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	for _, name := range outputFiles {
-		outPath := filepath.Join(buildDir, name)
-		err = os.WriteFile(outPath, []byte(html), 0644)
+	pages := make([]page, 0, len(routes))
+	for _, route := range routes {
+		// The {{.Title}}-style substitution runs on the raw, untouched
+		// index.html first, while it's still exactly what's on disk. Only
+		// after that do we fold in the asset pipeline's CSS/JS, which may
+		// contain arbitrary braces (a stray "{{" in minified JS, say) that
+		// would otherwise confuse html/template.
+		tmpl, err := template.New(route.File).Parse(string(htmlBytes))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", name, err)
-			os.Exit(1)
+			return nil, fmt.Errorf("parsing template for %s: %w", route.File, err)
+		}
+		var titled strings.Builder
+		if err := tmpl.Execute(&titled, route); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", route.File, err)
 		}
-		fmt.Printf("Built: %s\n", outPath)
-	}
 
-	fmt.Println("Build complete.")
-}
+		routeDoc, err := parseHTML(titled.String())
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", route.File, err)
+		}
 
-// replaceLinkTag replaces <link rel="stylesheet" href="styles.css"> with inline CSS
-func replaceLinkTag(html, inlineCSS string) string {
-	// Find and replace the stylesheet link
-	lines := strings.Split(html, "\n")
-	var result []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.Contains(trimmed, "styles.css") && strings.Contains(trimmed, "<link") {
-			result = append(result, inlineCSS)
-		} else {
-			result = append(result, line)
+		if err := rewriteStylesheets(routeDoc, cssInline, cssExternalTags); err != nil {
+			return nil, err
+		}
+
+		inlineAppJS := "<script>\nvar __CRISSY_DATA__ = " + jsonData + ";\n"
+		if route.ActiveSection != "" {
+			inlineAppJS += fmt.Sprintf("var __CRISSY_ACTIVE_SECTION__ = %q;\n", route.ActiveSection)
 		}
+		inlineAppJS += "</script>\n"
+		inlineAppJS += "<script>\n" + appJS + "\n</script>"
+
+		if err := rewriteAppScript(routeDoc, appScriptName, inlineAppJS); err != nil {
+			return nil, err
+		}
+		if err := rewriteOtherScripts(routeDoc, appScriptName, pipelineJS, jsExternalTags); err != nil {
+			return nil, err
+		}
+
+		pageHTML, err := renderHTMLString(routeDoc)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", route.File, err)
+		}
+
+		// Plugins can add their own inline <script>/<style> fragments, so the
+		// CSP meta tag is computed from their output, not before it - a hash
+		// list that missed a plugin's own script would just make a strict
+		// policy block it.
+		rendered, err := runPlugins(&BuildContext{Dir: dir, Route: route, CSP: cspCfg}, []byte(pageHTML))
+		if err != nil {
+			return nil, fmt.Errorf("running plugins for %s: %w", route.File, err)
+		}
+		pageHTML = string(rendered)
+
+		cspMeta := buildCSPMeta(cspCfg, inlineTagBodies(pageHTML, "script"), inlineTagBodies(pageHTML, "style"))
+		pageHTML = strings.Replace(pageHTML, "<head>", "<head>\n"+cspMeta, 1)
+
+		pages = append(pages, page{Route: route, HTML: pageHTML})
 	}
-	return strings.Join(result, "\n")
+
+	return pages, nil
 }
 
-// replaceScriptTag replaces <script src="scripts.js"></script> with inline JS
-func replaceScriptTag(html, inlineJS string) string {
-	lines := strings.Split(html, "\n")
-	var result []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.Contains(trimmed, "scripts.js") && strings.Contains(trimmed, "<script") {
-			result = append(result, inlineJS)
-		} else {
-			result = append(result, line)
-		}
+// inlineTagBodies returns the text content of every inline <tag>...</tag>
+// block in html (e.g. every <script> or <style> block with no src/href,
+// since by this point all external references have already been folded
+// into inline blocks). Used to compute per-block CSP hashes.
+func inlineTagBodies(html, tag string) []string {
+	re := regexp.MustCompile(`(?is)<` + tag + `(?:\s[^>]*)?>(.*?)</` + tag + `>`)
+	var bodies []string
+	for _, m := range re.FindAllStringSubmatch(html, -1) {
+		bodies = append(bodies, m[1])
 	}
-	return strings.Join(result, "\n")
+	return bodies
 }
 
 // patchJSForInline modifies the JS to use embedded data instead of XHR
@@ -134,20 +255,11 @@ func patchJSForInline(js string) string {
 	if startIdx == -1 {
 		return js
 	}
+	openIdx := startIdx + len(startMarker) - 1 // index of the '{'
 
-	// Find the matching closing brace by counting braces
-	braceCount := 0
-	endIdx := startIdx
-	for i := startIdx; i < len(js); i++ {
-		if js[i] == '{' {
-			braceCount++
-		} else if js[i] == '}' {
-			braceCount--
-			if braceCount == 0 {
-				endIdx = i + 1
-				break
-			}
-		}
+	endIdx, ok := matchingBrace(js, openIdx)
+	if !ok {
+		return js
 	}
 
 	return js[:startIdx] + replacement + js[endIdx:]
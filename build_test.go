@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSite lays out a minimal portfolio source tree under a temp dir so
+// renderSite can be exercised end-to-end without the repo's real assets.
+func writeSite(t *testing.T, scriptsJS string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.html": `<html><head><title>{{.Title}}</title>
+<link rel="stylesheet" href="styles.css">
+</head><body>
+<script src="scripts.js"></script>
+</body></html>`,
+		"styles.css":       "body { color: red; }",
+		"scripts.js":       scriptsJS,
+		"crissy-data.json": `{"name":"test"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRenderSiteSurvivesBracesInInlinedJS(t *testing.T) {
+	// The exact repro from the chunk0-2 review: a scripts.js whose inlined
+	// body happens to contain an adjacent "{{" must not reach text/template
+	// before its own {{.Title}}-style substitution has already run.
+	dir := writeSite(t, `function loadData(callback) {
+  try {
+    siteData = __CRISSY_DATA__;
+    callback(null, siteData);
+  } catch (e) {
+    callback(e, null);
+  }
+}
+function f(x) { if (x) {{ return 1; }} }`)
+
+	pages, err := renderSite(dir, assetOptions{})
+	if err != nil {
+		t.Fatalf("renderSite: %v", err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("renderSite returned no pages")
+	}
+	for _, p := range pages {
+		if !strings.Contains(p.HTML, "<title>") {
+			t.Errorf("%s: missing rendered title in %q", p.Route.File, p.HTML)
+		}
+	}
+}
+
+// markerPlugin appends a distinctive inline <script> so tests can confirm
+// both that plugin output made it into a build and that it got hashed.
+type markerPlugin struct{}
+
+func (markerPlugin) Name() string { return "marker" }
+
+func (markerPlugin) Transform(_ *BuildContext, html []byte) ([]byte, error) {
+	const snippet = "<script>console.log('plugin')</script>"
+	return []byte(strings.Replace(string(html), "</body>", snippet+"</body>", 1)), nil
+}
+
+// withPlugin registers p for the duration of the test and restores whatever
+// was registered before, so tests don't leak plugins into each other.
+func withPlugin(t *testing.T, p Plugin) {
+	t.Helper()
+	prev := registeredPlugins
+	registeredPlugins = nil
+	RegisterPlugin(p)
+	t.Cleanup(func() { registeredPlugins = prev })
+}
+
+func TestBuildStaticAppliesCSPAndPlugins(t *testing.T) {
+	dir := writeSite(t, `function loadData(callback) { callback(null, {}); }`)
+	if err := os.WriteFile(filepath.Join(dir, "csp.json"), []byte(`{"strict": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withPlugin(t, markerPlugin{})
+
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := buildStatic(dir, buildDir); err != nil {
+		t.Fatalf("buildStatic: %v", err)
+	}
+
+	out, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(out)
+
+	if !strings.Contains(html, "Content-Security-Policy") {
+		t.Errorf("buildStatic output missing CSP meta tag:\n%s", html)
+	}
+	if !strings.Contains(html, "console.log('plugin')") {
+		t.Errorf("buildStatic output missing plugin-injected script:\n%s", html)
+	}
+
+	pluginHash := inlineHash256("console.log('plugin')")
+	if !strings.Contains(html, pluginHash) {
+		t.Errorf("buildStatic output CSP meta missing plugin script hash %q:\n%s", pluginHash, html)
+	}
+}
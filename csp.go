@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// csp.go - synthesizes a Content-Security-Policy <meta> tag for each page.
+// By default script-src/style-src allow 'unsafe-inline' for the inlined
+// assets; a csp.json in the site directory can extend the allowed hosts or
+// switch to "strict" mode, which drops 'unsafe-inline' in favor of a
+// sha256 hash per inline <script>/<style> block.
+
+// CSPConfig is optionally loaded from csp.json to extend the default policy.
+type CSPConfig struct {
+	ScriptSrc []string `json:"script_src,omitempty"`
+	StyleSrc  []string `json:"style_src,omitempty"`
+	ImgSrc    []string `json:"img_src,omitempty"`
+	Strict    bool     `json:"strict,omitempty"`
+}
+
+// loadCSPConfig reads csp.json from dir, or returns an empty (default)
+// config if the file doesn't exist.
+func loadCSPConfig(dir string) (*CSPConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "csp.json"))
+	if os.IsNotExist(err) {
+		return &CSPConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading csp.json: %w", err)
+	}
+	cfg := &CSPConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing csp.json: %w", err)
+	}
+	return cfg, nil
+}
+
+// inlineHash256 computes a CSP "'sha256-...'" source for an inline
+// <script>/<style> block's exact text content.
+func inlineHash256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// buildCSPMeta synthesizes a Content-Security-Policy <meta> tag from the
+// default policy, csp.json overrides, and (in strict mode) hashes of the
+// page's own inline script/style blocks.
+func buildCSPMeta(cfg *CSPConfig, inlineScripts, inlineStyles []string) string {
+	scriptSrc := []string{"'self'"}
+	styleSrc := []string{"'self'"}
+
+	if cfg.Strict {
+		for _, s := range inlineScripts {
+			scriptSrc = append(scriptSrc, inlineHash256(s))
+		}
+		for _, s := range inlineStyles {
+			styleSrc = append(styleSrc, inlineHash256(s))
+		}
+	} else {
+		scriptSrc = append(scriptSrc, "'unsafe-inline'")
+		styleSrc = append(styleSrc, "'unsafe-inline'")
+	}
+
+	scriptSrc = append(scriptSrc, cfg.ScriptSrc...)
+	styleSrc = append(styleSrc, cfg.StyleSrc...)
+	imgSrc := append([]string{"'self'"}, cfg.ImgSrc...)
+
+	directives := []string{
+		"script-src " + strings.Join(scriptSrc, " "),
+		"style-src " + strings.Join(styleSrc, " "),
+		"img-src " + strings.Join(imgSrc, " "),
+	}
+	sort.Strings(directives)
+
+	return fmt.Sprintf(`<meta http-equiv="Content-Security-Policy" content="%s">`, strings.Join(directives, "; "))
+}
+
+// cspMetaTagPattern matches the <meta> tag buildCSPMeta produces.
+var cspMetaTagPattern = regexp.MustCompile(`<meta http-equiv="Content-Security-Policy"[^>]*>`)
+
+// recomputeCSPMeta replaces html's existing CSP <meta> tag, if any, with one
+// freshly hashed from html's current inline <script>/<style> content. Used
+// after a post-processing step (e.g. injectPrintStyle) mutates inline
+// content that was already hashed once.
+func recomputeCSPMeta(html string, cfg *CSPConfig) string {
+	meta := buildCSPMeta(cfg, inlineTagBodies(html, "script"), inlineTagBodies(html, "style"))
+	if cspMetaTagPattern.MatchString(html) {
+		return cspMetaTagPattern.ReplaceAllLiteralString(html, meta)
+	}
+	return strings.Replace(html, "<head>", "<head>\n"+meta, 1)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCSPMetaStrictHashesInlineContent(t *testing.T) {
+	cfg := &CSPConfig{Strict: true}
+
+	meta := buildCSPMeta(cfg, []string{"console.log('hi')"}, []string{"body{color:red}"})
+
+	wantScript := inlineHash256("console.log('hi')")
+	wantStyle := inlineHash256("body{color:red}")
+
+	if !strings.Contains(meta, wantScript) {
+		t.Errorf("meta = %q, want it to contain script hash %q", meta, wantScript)
+	}
+	if !strings.Contains(meta, wantStyle) {
+		t.Errorf("meta = %q, want it to contain style hash %q", meta, wantStyle)
+	}
+	if strings.Contains(meta, "'unsafe-inline'") {
+		t.Errorf("meta = %q, strict mode should not fall back to 'unsafe-inline'", meta)
+	}
+}
+
+func TestBuildCSPMetaDefaultAllowsUnsafeInline(t *testing.T) {
+	meta := buildCSPMeta(&CSPConfig{}, nil, nil)
+	if !strings.Contains(meta, "'unsafe-inline'") {
+		t.Errorf("meta = %q, want 'unsafe-inline' in non-strict mode", meta)
+	}
+}
+
+func TestRecomputeCSPMetaAfterMutation(t *testing.T) {
+	cfg := &CSPConfig{Strict: true}
+
+	original := "body{color:red}"
+	html := "<head>\n" + buildCSPMeta(cfg, nil, []string{original}) + "\n<style>" + original + "</style></head>"
+
+	mutated := strings.Replace(html, "</style>", printStyleBlock+"</style>", 1)
+	recomputed := recomputeCSPMeta(mutated, cfg)
+
+	staleHash := inlineHash256(original)
+	if strings.Contains(recomputed, staleHash) {
+		t.Errorf("recomputed meta still contains the pre-mutation hash %q", staleHash)
+	}
+
+	freshHash := inlineHash256(original + printStyleBlock)
+	if !strings.Contains(recomputed, freshHash) {
+		t.Errorf("recomputed meta = %q, want it to contain the post-mutation hash %q", recomputed, freshHash)
+	}
+}
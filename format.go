@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cdppage "github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// format.go - output formats for the build, selected with --format:
+//
+//   - single (default): everything inlined into one HTML file per route.
+//   - static: styles.css, scripts.js, and crissy-data.json are copied
+//     alongside templated HTML pages that keep their original external
+//     <link>/<script> references, so browsers (and GitHub Pages) can cache
+//     them separately.
+//   - pdf: the single-file HTML for the first route is rendered through
+//     headless Chrome into a print-styled PDF résumé.
+
+const (
+	formatSingle = "single"
+	formatStatic = "static"
+	formatPDF    = "pdf"
+)
+
+// printStyleBlock is appended to the inlined <style> for pdf builds so the
+// résumé renders sensibly on paper instead of like a web page.
+const printStyleBlock = `
+@media print {
+  nav, .site-nav, .live-reload { display: none !important; }
+  body { background: #fff; color: #000; }
+  a { color: inherit; text-decoration: none; }
+}
+`
+
+// buildStatic writes styles.css, scripts.js, and crissy-data.json unchanged
+// into buildDir, and renders index.html as a template per route without
+// running it through the asset pipeline, so the original external
+// <link>/<script> tags are preserved.
+func buildStatic(dir, buildDir string) error {
+	for _, name := range []string{"styles.css", "scripts.js", "crissy-data.json"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(buildDir, name), content, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		fmt.Printf("Copied: %s\n", filepath.Join(buildDir, name))
+	}
+
+	htmlBytes, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("reading index.html: %w", err)
+	}
+	jsonBytes, err := os.ReadFile(filepath.Join(dir, "crissy-data.json"))
+	if err != nil {
+		return fmt.Errorf("reading crissy-data.json: %w", err)
+	}
+
+	var doc routesDoc
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return fmt.Errorf("parsing crissy-data.json: %w", err)
+	}
+	routes := doc.Routes
+	if len(routes) == 0 {
+		routes = defaultRoutes()
+	}
+
+	cspCfg, err := loadCSPConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		tmpl, err := template.New(route.File).Parse(string(htmlBytes))
+		if err != nil {
+			return fmt.Errorf("parsing template for %s: %w", route.File, err)
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, route); err != nil {
+			return fmt.Errorf("rendering %s: %w", route.File, err)
+		}
+
+		// Run the same CSP/plugin steps --format single does, so a static
+		// build isn't silently missing the CSP meta tag or plugin output.
+		// The page's own <link>/<script> tags stay external either way;
+		// only a plugin's own inline fragments (if any) get hashed here.
+		rendered, err := runPlugins(&BuildContext{Dir: dir, Route: route, CSP: cspCfg}, []byte(buf.String()))
+		if err != nil {
+			return fmt.Errorf("running plugins for %s: %w", route.File, err)
+		}
+		pageHTML := string(rendered)
+
+		cspMeta := buildCSPMeta(cspCfg, inlineTagBodies(pageHTML, "script"), inlineTagBodies(pageHTML, "style"))
+		pageHTML = strings.Replace(pageHTML, "<head>", "<head>\n"+cspMeta, 1)
+
+		outPath := filepath.Join(buildDir, route.File)
+		if err := os.WriteFile(outPath, []byte(pageHTML), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", route.File, err)
+		}
+		fmt.Printf("Built: %s\n", outPath)
+	}
+
+	fmt.Println("Build complete.")
+	return nil
+}
+
+// buildPDF renders the inlined HTML for the first route through headless
+// Chrome into a print-styled PDF résumé at build/resume.pdf.
+func buildPDF(dir, buildDir string, opts assetOptions) error {
+	pages, err := renderSite(dir, opts)
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("no routes to render")
+	}
+
+	cspCfg, err := loadCSPConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	// injectPrintStyle mutates the inline <style> block renderSite already
+	// hashed into the page's CSP meta tag, so the meta tag has to be
+	// recomputed from the post-injection content or a strict policy drops
+	// the very style block this format exists to add.
+	html := recomputeCSPMeta(injectPrintStyle(pages[0].HTML), cspCfg)
+
+	pdfBytes, err := renderPDF(html)
+	if err != nil {
+		return fmt.Errorf("rendering pdf: %w", err)
+	}
+
+	outPath := filepath.Join(buildDir, "resume.pdf")
+	if err := os.WriteFile(outPath, pdfBytes, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", outPath, err)
+	}
+	fmt.Printf("Built: %s\n", outPath)
+	fmt.Println("Build complete.")
+	return nil
+}
+
+// injectPrintStyle adds printStyleBlock just before the closing </style> tag
+// of the page's inlined stylesheet.
+func injectPrintStyle(html string) string {
+	idx := strings.LastIndex(html, "</style>")
+	if idx == -1 {
+		return html
+	}
+	return html[:idx] + printStyleBlock + html[idx:]
+}
+
+// renderPDF serves html over a local HTTP listener and drives headless
+// Chrome to print it to PDF, so relative asset paths and @media print rules
+// behave exactly as they would in a real browser.
+func renderPDF(html string) ([]byte, error) {
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, html)
+		}),
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("starting local server: %w", err)
+	}
+	defer ln.Close()
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	ctx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, 30*time.Second)
+	defer cancelTimeout()
+
+	url := fmt.Sprintf("http://%s/", ln.Addr().String())
+
+	var pdfBuf []byte
+	err = chromedp.Run(ctx,
+		chromedp.Navigate(url),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			buf, _, err := cdppage.PrintToPDF().WithPrintBackground(true).Do(ctx)
+			if err != nil {
+				return err
+			}
+			pdfBuf = buf
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pdfBuf, nil
+}
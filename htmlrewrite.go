@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlrewrite.go - HTML rewriting via golang.org/x/net/html instead of
+// line/regex-based scanning, so a <link>/<script> tag that spans multiple
+// lines, uses single quotes, carries extra attributes, or sits inside an
+// HTML comment doesn't silently break the build.
+
+// parseHTML parses a full HTML document.
+func parseHTML(src string) (*html.Node, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	return doc, nil
+}
+
+// renderHTMLString serializes a parsed document (or any node) back to a string.
+func renderHTMLString(n *html.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func walk(n *html.Node, visit func(*html.Node)) {
+	visit(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, visit)
+	}
+}
+
+// findStylesheetRefs walks doc for every <link rel="stylesheet" href="...">
+// node, in source order.
+func findStylesheetRefs(doc *html.Node) []string {
+	var refs []string
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link && attr(n, "rel") == "stylesheet" {
+			if href := attr(n, "href"); href != "" {
+				refs = append(refs, href)
+			}
+		}
+	})
+	return refs
+}
+
+// findScriptRefs walks doc for every external <script src="..."> node, in
+// source order.
+func findScriptRefs(doc *html.Node) []string {
+	var refs []string
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Script {
+			if src := attr(n, "src"); src != "" {
+				refs = append(refs, src)
+			}
+		}
+	})
+	return refs
+}
+
+// rewriteStylesheets replaces every <link rel="stylesheet"> node in doc: the
+// first becomes the parsed fragment of inline (plus externalTags), the rest
+// are removed, since their content is already folded into inline.
+func rewriteStylesheets(doc *html.Node, inline string, externalTags []string) error {
+	return rewriteNodes(doc, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.DataAtom == atom.Link && attr(n, "rel") == "stylesheet"
+	}, inline, externalTags)
+}
+
+// rewriteAppScript replaces the single <script src=appSrc> node with the
+// parsed fragment of appJS (the app's own logic plus per-route data).
+func rewriteAppScript(doc *html.Node, appSrc, appJS string) error {
+	return rewriteNodes(doc, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.DataAtom == atom.Script && attr(n, "src") == appSrc
+	}, appJS, nil)
+}
+
+// rewriteOtherScripts replaces every remaining external <script src> node
+// (any src other than skipSrc), the same way rewriteStylesheets does.
+func rewriteOtherScripts(doc *html.Node, skipSrc, inline string, externalTags []string) error {
+	return rewriteNodes(doc, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.DataAtom == atom.Script && attr(n, "src") != "" && attr(n, "src") != skipSrc
+	}, inline, externalTags)
+}
+
+// rewriteNodes finds every node matching match, in document order, and
+// replaces the first with the parsed fragment of raw markup (inline plus
+// externalTags), removing the rest.
+func rewriteNodes(doc *html.Node, match func(*html.Node) bool, inline string, externalTags []string) error {
+	if inline == "" && len(externalTags) == 0 {
+		return nil
+	}
+
+	var nodes []*html.Node
+	walk(doc, func(n *html.Node) {
+		if match(n) {
+			nodes = append(nodes, n)
+		}
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	raw := strings.Join(append([]string{inline}, externalTags...), "\n")
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	fragment, err := html.ParseFragment(strings.NewReader(raw), context)
+	if err != nil {
+		return fmt.Errorf("parsing replacement markup: %w", err)
+	}
+
+	first := nodes[0]
+	for _, f := range fragment {
+		first.Parent.InsertBefore(f, first)
+	}
+	first.Parent.RemoveChild(first)
+
+	for _, n := range nodes[1:] {
+		n.Parent.RemoveChild(n)
+	}
+	return nil
+}
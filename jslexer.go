@@ -0,0 +1,188 @@
+package main
+
+import "strings"
+
+// jslexer.go - a small JS-aware scanner used to find the matching closing
+// brace for a function body. A naive counter gets fooled by braces that
+// appear inside a string, a template literal, a comment, or a regex
+// literal; this one skips over all of those.
+
+// matchingBrace returns the index just past the '}' that matches the '{' at
+// openIdx.
+func matchingBrace(js string, openIdx int) (int, bool) {
+	if openIdx >= len(js) || js[openIdx] != '{' {
+		return 0, false
+	}
+
+	depth := 0
+	i := openIdx
+	var prevSignificant byte
+	var lastWord string
+	var word strings.Builder
+
+	for i < len(js) {
+		c := js[i]
+
+		if isIdentByte(c) {
+			word.WriteByte(c)
+			prevSignificant = c
+			i++
+			continue
+		}
+		if word.Len() > 0 {
+			lastWord = word.String()
+			word.Reset()
+		}
+
+		switch {
+		case c == '/' && i+1 < len(js) && js[i+1] == '/':
+			i = skipLineComment(js, i)
+		case c == '/' && i+1 < len(js) && js[i+1] == '*':
+			i = skipBlockComment(js, i)
+		case c == '\'' || c == '"' || c == '`':
+			i = skipStringLiteral(js, i, c)
+			prevSignificant = c
+			lastWord = ""
+		case c == '/' && regexAllowed(prevSignificant, lastWord):
+			i = skipRegexLiteral(js, i)
+			prevSignificant = '/'
+			lastWord = ""
+		case c == '{':
+			depth++
+			prevSignificant = c
+			lastWord = ""
+			i++
+		case c == '}':
+			depth--
+			i++
+			if depth == 0 {
+				return i, true
+			}
+			prevSignificant = c
+			lastWord = ""
+		default:
+			if !isJSSpace(c) {
+				prevSignificant = c
+				lastWord = ""
+			}
+			i++
+		}
+	}
+
+	return 0, false
+}
+
+func isJSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isIdentByte reports whether c can appear in a JS identifier or keyword.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// regexKeywords are the keywords after which a '/' starts a regex literal
+// rather than ending a division expression, e.g. "return /foo/.test(s)".
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "delete": true,
+	"in": true, "of": true, "instanceof": true, "new": true,
+	"void": true, "yield": true, "throw": true, "do": true,
+	"else": true, "await": true,
+}
+
+// regexAllowed reports whether a '/' at this point in the scan should be
+// treated as the start of a regex literal rather than a division operator,
+// based on the previous significant (non-space, non-comment) character and,
+// if that character ended an identifier, the word it belonged to - so
+// "return /x/.test(s)" isn't misread as division just because the scanner
+// only looked at the single preceding byte.
+func regexAllowed(prev byte, lastWord string) bool {
+	switch prev {
+	case 0, '(', '[', '{', ',', ';', ':', '=', '!', '&', '|', '?', '+', '-', '*', '%', '^', '~', '<', '>':
+		return true
+	}
+	return regexKeywords[lastWord]
+}
+
+func skipLineComment(js string, i int) int {
+	for i < len(js) && js[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+func skipBlockComment(js string, i int) int {
+	i += 2
+	for i+1 < len(js) && !(js[i] == '*' && js[i+1] == '/') {
+		i++
+	}
+	if i+1 < len(js) {
+		return i + 2
+	}
+	return len(js)
+}
+
+// skipStringLiteral skips a single/double-quoted string or a template
+// literal (backtick), starting at the opening quote, and returns the index
+// just past its end. A template literal's "${...}" interpolations are
+// skipped with the full scanner (skipTemplateExpr), not by just looking for
+// the next backtick - otherwise a nested literal like `${`inner`}` closes
+// the outer one early at "inner"'s backtick.
+func skipStringLiteral(js string, i int, quote byte) int {
+	i++ // past opening quote
+	for i < len(js) {
+		switch {
+		case js[i] == '\\':
+			i += 2
+		case js[i] == quote:
+			return i + 1
+		case quote == '`' && js[i] == '$' && i+1 < len(js) && js[i+1] == '{':
+			i = skipTemplateExpr(js, i+1)
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// skipTemplateExpr skips a template literal's "${...}" interpolation
+// expression, given the index of its '{', and returns the index just past
+// the matching '}'. It reuses matchingBrace's full scanner, so a nested
+// template literal, string, comment, or regex inside the expression is
+// handled the same way it would be anywhere else in the source.
+func skipTemplateExpr(js string, openIdx int) int {
+	end, ok := matchingBrace(js, openIdx)
+	if !ok {
+		return len(js)
+	}
+	return end
+}
+
+func skipRegexLiteral(js string, i int) int {
+	i++ // past opening '/'
+	inClass := false
+	for i < len(js) {
+		switch js[i] {
+		case '\\':
+			i += 2
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i // unterminated; bail out and let the caller recover
+		case '/':
+			if !inClass {
+				i++
+				for i < len(js) && strings.IndexByte("gimsuy", js[i]) >= 0 {
+					i++
+				}
+				return i
+			}
+		}
+		i++
+	}
+	return i
+}
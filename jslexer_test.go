@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchingBrace(t *testing.T) {
+	tests := []struct {
+		name string
+		js   string
+		open int
+		want string // js[got_end-?:got_end] isn't stable, so we check the substring from open to the match instead
+	}{
+		{
+			name: "plain function",
+			js:   "function f(x) { return x + 1; }",
+			open: 14,
+			want: "{ return x + 1; }",
+		},
+		{
+			name: "brace inside string",
+			js:   `function f() { return "{"; }`,
+			open: 13,
+			want: `{ return "{"; }`,
+		},
+		{
+			name: "brace inside regex after return",
+			js: "function hasBrace(s) { return /\\{/.test(s); }\n" +
+				"return hasBrace(\"{\");",
+			open: 21,
+			want: "{ return /\\{/.test(s); }",
+		},
+		{
+			name: "division is not a regex",
+			js:   "function f(a, b) { return a / b; }",
+			open: 17,
+			want: "{ return a / b; }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := matchingBrace(tt.js, tt.open)
+			if !ok {
+				t.Fatalf("matchingBrace(%d) = !ok, want match", tt.open)
+			}
+			got := tt.js[tt.open:end]
+			if got != tt.want {
+				t.Errorf("matchingBrace(%d) = %q, want %q", tt.open, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchingBraceFromReview(t *testing.T) {
+	// The exact repro from the chunk0-6 review: a regex literal right after
+	// "return" must not be mistaken for division, or the outer function's
+	// closing brace is never found.
+	js := "function loadData(callback) {\n" +
+		" function hasBrace(s) { return /\\{/.test(s); }\n" +
+		" return hasBrace(\"{\");\n" +
+		"}"
+
+	open := 28 // index of the outer function's '{'
+	if js[open] != '{' {
+		t.Fatalf("test setup: js[%d] = %q, want '{'", open, js[open])
+	}
+
+	end, ok := matchingBrace(js, open)
+	if !ok {
+		t.Fatal("matchingBrace on outer function = !ok, want match")
+	}
+	if end != len(js) {
+		t.Errorf("matchingBrace end = %d, want %d (end of string)", end, len(js))
+	}
+}
+
+func TestMatchingBraceNestedTemplateLiteral(t *testing.T) {
+	// A template literal whose "${...}" interpolation itself contains a
+	// nested template literal (with its own backticks and braces) must not
+	// close the outer literal early at the inner literal's backtick.
+	js := "function f(x) { var s = `${`inner ${x}`}`; return s; }"
+
+	open := strings.Index(js, "{")
+	if open == -1 {
+		t.Fatal("test setup: no '{' found")
+	}
+
+	end, ok := matchingBrace(js, open)
+	if !ok {
+		t.Fatal("matchingBrace = !ok, want match")
+	}
+	if end != len(js) {
+		t.Errorf("matchingBrace end = %d (stopped at %q), want %d (end of string)", end, js[end:], len(js))
+	}
+}
+
+func TestRegexAllowed(t *testing.T) {
+	tests := []struct {
+		prev     byte
+		lastWord string
+		want     bool
+	}{
+		{prev: '(', lastWord: "", want: true},
+		{prev: 'n', lastWord: "return", want: true},
+		{prev: 'f', lastWord: "typeof", want: true},
+		{prev: 'x', lastWord: "x", want: false},
+		{prev: '5', lastWord: "5", want: false},
+		{prev: ')', lastWord: "", want: false},
+	}
+
+	for _, tt := range tests {
+		got := regexAllowed(tt.prev, tt.lastWord)
+		if got != tt.want {
+			t.Errorf("regexAllowed(%q, %q) = %v, want %v", tt.prev, tt.lastWord, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+)
+
+// plugins.go - plugin hooks for custom build transformations.
+//
+// A plugin is a Go package built with -buildmode=plugin into a .so file and
+// dropped into the site's plugins/ directory:
+//
+//	go build -buildmode=plugin -o plugins/analytics.so ./cmd/analytics-plugin
+//
+// exporting a package-level symbol named Plugin that implements the Plugin
+// interface below, e.g. `var Plugin analyticsPlugin`. discoverPlugins scans
+// plugins/*.so and registers whatever it finds there - no blank import or
+// rebuild of the builder itself needed to pick up a new plugin.
+//
+// The builder runs every registered plugin's Transform over each route's
+// rendered HTML, in registration order.
+
+// Plugin lets build.go be extended with custom HTML transformations:
+// additional CSS/JS fragments, extra <meta> tags, or anything else that
+// needs to see (and adjust) the final page.
+type Plugin interface {
+	Name() string
+	Transform(ctx *BuildContext, html []byte) ([]byte, error)
+}
+
+// BuildContext carries the state a Plugin needs to inspect or extend the
+// page currently being built.
+type BuildContext struct {
+	Dir   string
+	Route Route
+	CSP   *CSPConfig
+}
+
+var registeredPlugins []Plugin
+
+// RegisterPlugin adds a Plugin to the build. Call it from an init() func.
+func RegisterPlugin(p Plugin) {
+	registeredPlugins = append(registeredPlugins, p)
+}
+
+// runPlugins threads html through every registered plugin in order.
+func runPlugins(ctx *BuildContext, html []byte) ([]byte, error) {
+	for _, p := range registeredPlugins {
+		out, err := p.Transform(ctx, html)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+		html = out
+	}
+	return html, nil
+}
+
+// loadedPluginPaths tracks which .so files discoverPlugins has already
+// opened, so calling it again (e.g. on every serve rebuild) doesn't
+// re-register the same plugin twice.
+var loadedPluginPaths = map[string]bool{}
+
+// discoverPlugins loads every plugins/*.so file under dir and registers the
+// Plugin symbol it exports. It's a no-op if the site has no plugins/
+// directory.
+func discoverPlugins(dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, "plugins", "*.so"))
+	if err != nil {
+		return fmt.Errorf("scanning plugins directory: %w", err)
+	}
+
+	for _, path := range matches {
+		if loadedPluginPaths[path] {
+			continue
+		}
+
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("loading plugin %s: %w", path, err)
+		}
+		sym, err := p.Lookup("Plugin")
+		if err != nil {
+			return fmt.Errorf("plugin %s: no exported Plugin symbol: %w", path, err)
+		}
+		impl, ok := sym.(Plugin)
+		if !ok {
+			return fmt.Errorf("plugin %s: exported Plugin does not implement the Plugin interface", path)
+		}
+
+		RegisterPlugin(impl)
+		loadedPluginPaths[path] = true
+	}
+
+	return nil
+}
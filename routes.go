@@ -0,0 +1,35 @@
+package main
+
+// routes.go - route definitions for per-page generation.
+// crissy-data.json may declare a "routes" array; build.go renders index.html
+// as an html/template once per route so each page gets its own <title> and
+// meta tags instead of an identical copy.
+
+// Route describes one generated page: its output filename, SEO metadata,
+// and which nav section scripts.js should open on load.
+type Route struct {
+	File          string `json:"file"`
+	Title         string `json:"title"`
+	Description   string `json:"description,omitempty"`
+	OGImage       string `json:"og_image,omitempty"`
+	Canonical     string `json:"canonical,omitempty"`
+	ActiveSection string `json:"active_section,omitempty"`
+}
+
+// routesDoc is the subset of crissy-data.json build.go reads for page
+// generation; the rest of the document passes through untouched as
+// __CRISSY_DATA__.
+type routesDoc struct {
+	Routes []Route `json:"routes"`
+}
+
+// defaultRoutes preserves the original four-identical-pages behavior for
+// crissy-data.json files that don't declare a "routes" array yet.
+func defaultRoutes() []Route {
+	return []Route{
+		{File: "index.html", Title: "Crissy Moon"},
+		{File: "projects.html", Title: "Crissy Moon - Projects", ActiveSection: "projects"},
+		{File: "links.html", Title: "Crissy Moon - Links", ActiveSection: "links"},
+		{File: "about.html", Title: "Crissy Moon - About", ActiveSection: "about"},
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// serve.go - "serve" subcommand: watches the portfolio source files and
+// rebuilds the inlined HTML in-memory, serving it over HTTP with a
+// live-reload snippet so connected browsers auto-refresh on rebuild.
+
+// watchedFiles are the inputs that trigger a rebuild when changed.
+var watchedFiles = []string{
+	"index.html",
+	"styles.css",
+	"scripts.js",
+	"crissy-data.json",
+}
+
+// liveReloadScript is injected into the served HTML. It opens an SSE
+// connection to /__livereload and reloads the page on any message.
+const liveReloadScript = `<script>
+(function() {
+  var es = new EventSource("/__livereload");
+  es.onmessage = function() { location.reload(); };
+})();
+</script>
+`
+
+// devServer holds the current in-memory build and serves it over HTTP.
+type devServer struct {
+	dir string
+
+	mu    sync.RWMutex
+	pages map[string]string // route file, e.g. "projects.html" -> rendered HTML
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := fs.Int("port", 8080, "port to serve the portfolio on")
+	dir := fs.String("dir", ".", "directory containing the portfolio source files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	srv := &devServer{dir: *dir}
+	if err := srv.rebuild(); err != nil {
+		return fmt.Errorf("initial build failed: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, name := range watchedFiles {
+		if err := watcher.Add(filepath.Join(*dir, name)); err != nil {
+			return fmt.Errorf("watching %s: %w", name, err)
+		}
+	}
+
+	hub := newReloadHub()
+	go srv.watchLoop(watcher, hub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/__livereload", hub.serveSSE)
+	mux.HandleFunc("/", srv.serveHTML)
+
+	addr := fmt.Sprintf(":%d", *port)
+	fmt.Printf("Serving portfolio at http://localhost%s (watching for changes)\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// rebuild re-renders every route and swaps the whole set in atomically.
+func (s *devServer) rebuild() error {
+	if err := discoverPlugins(s.dir); err != nil {
+		return err
+	}
+
+	rendered, err := renderSite(s.dir, assetOptions{})
+	if err != nil {
+		return err
+	}
+
+	cspCfg, err := loadCSPConfig(s.dir)
+	if err != nil {
+		return err
+	}
+
+	// injectLiveReload adds an inline <script> after renderSite has already
+	// hashed the page's CSP meta tag, so the meta tag has to be recomputed
+	// from the post-injection content or a strict policy blocks the
+	// reload script itself - the same failure mode the PDF path hit.
+	pages := make(map[string]string, len(rendered))
+	for _, p := range rendered {
+		pages[p.Route.File] = recomputeCSPMeta(injectLiveReload(p.HTML), cspCfg)
+	}
+
+	s.mu.Lock()
+	s.pages = pages
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *devServer) serveHTML(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	s.mu.RLock()
+	html, ok := s.pages[name]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, html)
+}
+
+// watchLoop rebuilds on every relevant fsnotify event and notifies clients.
+func (s *devServer) watchLoop(watcher *fsnotify.Watcher, hub *reloadHub) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := s.rebuild(); err != nil {
+				log.Printf("rebuild failed: %v", err)
+				continue
+			}
+			log.Printf("rebuilt after change to %s", event.Name)
+			hub.broadcast()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("watcher error: %v", err)
+		}
+	}
+}
+
+// injectLiveReload adds the SSE-based live-reload snippet just before </body>.
+func injectLiveReload(html string) string {
+	marker := "</body>"
+	idx := strings.LastIndex(html, marker)
+	if idx == -1 {
+		return html + liveReloadScript
+	}
+	return html[:idx] + liveReloadScript + html[idx:]
+}
+
+// reloadHub fans out a reload notification to every connected browser.
+type reloadHub struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+}
+
+func newReloadHub() *reloadHub {
+	return &reloadHub{clients: make(map[chan struct{}]bool)}
+}
+
+func (h *reloadHub) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	h.clients[ch] = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, ch)
+		h.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ch:
+			fmt.Fprintf(w, "data: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (h *reloadHub) broadcast() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
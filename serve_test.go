@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecomputeCSPMetaAfterLiveReloadInject(t *testing.T) {
+	cfg := &CSPConfig{Strict: true}
+
+	script := "console.log('app')"
+	html := "<head>\n" + buildCSPMeta(cfg, []string{script}, nil) +
+		"\n</head><body><script>" + script + "</script></body>"
+
+	mutated := injectLiveReload(html)
+	recomputed := recomputeCSPMeta(mutated, cfg)
+
+	if strings.Contains(recomputed, "'unsafe-inline'") {
+		t.Errorf("recomputed meta = %q, strict mode should not fall back to 'unsafe-inline'", recomputed)
+	}
+
+	bodies := inlineTagBodies(mutated, "script")
+	if len(bodies) != 2 {
+		t.Fatalf("inlineTagBodies found %d <script> blocks in %q, want 2 (app + live-reload)", len(bodies), mutated)
+	}
+	liveReloadHash := inlineHash256(bodies[1])
+	if !strings.Contains(recomputed, liveReloadHash) {
+		t.Errorf("recomputed meta = %q, want it to contain the live-reload script's hash %q", recomputed, liveReloadHash)
+	}
+}